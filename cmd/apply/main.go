@@ -0,0 +1,107 @@
+// Command apply fetches the current tiflash placement rules from PD,
+// rewrites the ones belonging to a keyspace onto the enable_s3_wn_region
+// group, and pushes the result back as a single atomic batch update.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+const enableS3WnRegionGroup = "enable_s3_wn_region"
+
+func main() {
+	var (
+		pdAddr   = flag.String("pd-addr", "http://127.0.0.1:2379", "PD address, e.g. http://127.0.0.1:2379")
+		keyspace = flag.String("keyspace", "", "keyspace id/name whose tiflash rules should move to "+enableS3WnRegionGroup)
+		dryRun   = flag.Bool("dry-run", false, "compute the batch but do not POST it to PD")
+		diff     = flag.Bool("diff", false, "print the additions/removals the batch would make before applying")
+		caFile   = flag.String("ca-file", "", "PEM CA bundle to verify PD's TLS certificate")
+	)
+	flag.Parse()
+	if *keyspace == "" {
+		fmt.Fprintln(os.Stderr, "Usage: apply --pd-addr http://pd:2379 --keyspace 123")
+		os.Exit(1)
+	}
+
+	tlsConfig, err := buildTLSConfig(*caFile)
+	if err != nil {
+		panic(err)
+	}
+	client := pdclient.NewClient(pdclient.Config{Addr: *pdAddr, TLSConfig: tlsConfig})
+
+	ctx := context.Background()
+	curRules, err := client.GetRules(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	newRules, err := transformKeyspace(curRules, *keyspace)
+	if err != nil {
+		panic(err)
+	}
+
+	wantGroup := &pdclient.RuleGroup{ID: enableS3WnRegionGroup, Index: 1}
+	batch := pdclient.Diff(curRules, append(curRules, newRules...), wantGroup)
+
+	if *diff {
+		pdclient.PrintDiff(os.Stdout, batch)
+	}
+	if err := client.ApplyBatch(ctx, batch, *dryRun); err != nil {
+		panic(err)
+	}
+	if *dryRun {
+		fmt.Println("dry-run: batch not applied")
+	} else {
+		fmt.Println("batch applied")
+	}
+}
+
+// transformKeyspace mirrors the standalone enable.go rewrite: any tiflash
+// rule whose ID belongs to keyspace moves to enableS3WnRegionGroup with a
+// write-node-only label constraint.
+func transformKeyspace(cur pdclient.Rules, keyspace string) (pdclient.Rules, error) {
+	keyspacePrefix1 := fmt.Sprintf("keyspace-%s-", keyspace)
+	keyspacePrefix2 := fmt.Sprintf("keyspace-id-%s-", keyspace)
+
+	tiflashConstraint := pdclient.LabelConstraint{Key: "engine", Op: pdclient.In, Values: []string{"tiflash"}}
+	wnConstraint := pdclient.LabelConstraint{Key: "engine_role", Op: pdclient.In, Values: []string{"write"}}
+
+	var out pdclient.Rules
+	for _, rule := range cur {
+		if rule.GroupID != "tiflash" {
+			continue
+		}
+		if !strings.Contains(rule.ID, keyspacePrefix1) && !strings.Contains(rule.ID, keyspacePrefix2) {
+			continue
+		}
+		rule.GroupID = enableS3WnRegionGroup
+		rule.Index = 1
+		rule.Count = 1
+		rule.LabelConstraints = append([]pdclient.LabelConstraint{}, wnConstraint, tiflashConstraint)
+		out = append(out, rule)
+	}
+	return out, nil
+}
+
+func buildTLSConfig(caFile string) (*tls.Config, error) {
+	if caFile == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ca-file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
@@ -0,0 +1,150 @@
+// Command change_rules is the single entry point for placement-rule
+// migrations: `change_rules apply -f plan.yaml -i cur_rules.json` compiles
+// a declarative ruleplan.Plan against a rule dump and prints the result,
+// `change_rules validate -i cur_rules.json` checks a dump against PD's
+// semantic invariants, and `change_rules rollback --from before.json --to
+// after.json` emits the batch that undoes a migration. Replaces the old
+// check.go/enable.go/delete.go one-off binaries.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+	"github.com/guo-shaoge/change_rules/pkg/rollback"
+	"github.com/guo-shaoge/change_rules/pkg/ruleplan"
+	"github.com/guo-shaoge/change_rules/pkg/rulevalidate"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+	switch os.Args[1] {
+	case "apply":
+		runApply(os.Args[2:])
+	case "validate":
+		runValidate(os.Args[2:])
+	case "rollback":
+		runRollback(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: change_rules apply -f plan.yaml -i cur_rules.json")
+	fmt.Fprintln(os.Stderr, "       change_rules validate -i cur_rules.json [-max-replicas N]")
+	fmt.Fprintln(os.Stderr, "       change_rules rollback --from before.json --to after.json")
+}
+
+func runRollback(args []string) {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	fromFile := fs.String("from", "", "rule dump to restore (the state before the migration)")
+	toFile := fs.String("to", "", "rule dump to revert (the state after the migration)")
+	fs.Parse(args)
+	if *fromFile == "" || *toFile == "" {
+		usage()
+		os.Exit(1)
+	}
+
+	fromData, err := os.ReadFile(*fromFile)
+	if err != nil {
+		panic(err)
+	}
+	before, err := rollback.ParseDump(fromData)
+	if err != nil {
+		panic(err)
+	}
+
+	toData, err := os.ReadFile(*toFile)
+	if err != nil {
+		panic(err)
+	}
+	after, err := rollback.ParseDump(toData)
+	if err != nil {
+		panic(err)
+	}
+
+	batch := rollback.Generate(before, after)
+	out, err := json.MarshalIndent(batch, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+}
+
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	inFile := fs.String("i", "", "current rules JSON dump")
+	maxReplicas := fs.Int("max-replicas", 0, "flag overlapping ranges whose combined peer count exceeds this (0 disables the check)")
+	fs.Parse(args)
+	if *inFile == "" {
+		usage()
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*inFile)
+	if err != nil {
+		panic(err)
+	}
+	rules, err := rulevalidate.ParseWithOffsets(data)
+	if err != nil {
+		panic(err)
+	}
+
+	diags := rulevalidate.Validate(rules, rulevalidate.Options{MaxReplicas: *maxReplicas})
+	out, err := json.MarshalIndent(diags, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	if len(diags) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runApply(args []string) {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	planFile := fs.String("f", "", "declarative ruleplan YAML file")
+	inFile := fs.String("i", "", "current rules JSON dump")
+	fs.Parse(args)
+	if *planFile == "" || *inFile == "" {
+		usage()
+		os.Exit(1)
+	}
+
+	planData, err := os.ReadFile(*planFile)
+	if err != nil {
+		panic(err)
+	}
+	plan, err := ruleplan.Parse(planData)
+	if err != nil {
+		panic(err)
+	}
+
+	curData, err := os.ReadFile(*inFile)
+	if err != nil {
+		panic(err)
+	}
+	var curRules pdclient.Rules
+	if err := json.Unmarshal(curData, &curRules); err != nil {
+		panic(err)
+	}
+
+	newRules, err := ruleplan.Compile(plan, curRules)
+	if err != nil {
+		panic(err)
+	}
+
+	newData, err := json.MarshalIndent(newRules, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(newData))
+}
@@ -0,0 +1,111 @@
+// Package keyrange computes TiKV's keyspace-prefixed key ranges and
+// intersects a placement Rule's [StartKey, EndKey) against them, so a
+// rule spanning more than one keyspace can be split instead of rewritten
+// wholesale.
+package keyrange
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+// keyspacePrefixByte is TiKV's leading byte for keyspace-encoded keys:
+// 'x' + 3-byte big-endian keyspace id, then the original key.
+const keyspacePrefixByte = 'x'
+
+// Prefix encodes the 4-byte keyspace prefix for id: 'x' followed by id as
+// a 3-byte big-endian integer.
+func Prefix(id uint32) []byte {
+	return []byte{
+		keyspacePrefixByte,
+		byte(id >> 16),
+		byte(id >> 8),
+		byte(id),
+	}
+}
+
+// Range returns the canonical [start, end) key range owned by keyspace
+// id: every key prefixed by id's 4-byte prefix, up to (but excluding) the
+// next keyspace's prefix.
+func Range(id uint32) (start, end []byte) {
+	return Prefix(id), Prefix(id + 1)
+}
+
+// Split intersects rule's [StartKey, EndKey) with [ksStart, ksEnd),
+// returning up to three rules: the portion before ksStart, the portion
+// inside [ksStart, ksEnd), and the portion after ksEnd. A nil result
+// means that portion of the original range is empty. An empty
+// rule.EndKeyHex means "no upper bound".
+//
+// inside keeps rule's ID; before/after get an ID suffix so the three
+// parts don't collide under PD's (GroupID,ID) uniqueness requirement.
+func Split(rule pdclient.Rule, ksStart, ksEnd []byte) (before, inside, after *pdclient.Rule, err error) {
+	start, err := hex.DecodeString(rule.StartKeyHex)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	end, err := hex.DecodeString(rule.EndKeyHex)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	insideStart := start
+	if bytes.Compare(ksStart, start) > 0 {
+		insideStart = ksStart
+	}
+	insideEnd := ksEnd
+	if len(end) > 0 && bytes.Compare(end, ksEnd) < 0 {
+		insideEnd = end
+	}
+	if bytes.Compare(insideStart, insideEnd) < 0 {
+		r := withRange(rule, insideStart, insideEnd)
+		inside = &r
+	}
+
+	// The before/after portions must stay within the rule's own bounds:
+	// clamping them to ksStart/ksEnd unconditionally would widen a rule
+	// that doesn't even reach the keyspace into covering whatever lies
+	// between its own range and the keyspace's.
+	beforeEnd := ksStart
+	if len(end) > 0 && bytes.Compare(end, ksStart) < 0 {
+		beforeEnd = end
+	}
+	if bytes.Compare(start, beforeEnd) < 0 {
+		r := withRange(rule, start, beforeEnd)
+		before = &r
+	}
+
+	afterStart := ksEnd
+	if bytes.Compare(start, ksEnd) > 0 {
+		afterStart = start
+	}
+	if len(end) == 0 || bytes.Compare(afterStart, end) < 0 {
+		r := withRange(rule, afterStart, end)
+		after = &r
+	}
+
+	// Only suffix before/after when the rule was actually split into more
+	// than one piece: with inside == nil and only one of before/after
+	// produced, that piece IS the original rule untouched, so renaming it
+	// would misrepresent a pass-through as a split, causing
+	// pdclient.Diff/rollback.Generate (which key on (GroupID,ID)) to see a
+	// spurious delete-and-recreate of an unrelated keyspace's rule.
+	if before != nil && (inside != nil || after != nil) {
+		before.ID += "-before"
+	}
+	if after != nil && (inside != nil || before != nil) {
+		after.ID += "-after"
+	}
+
+	return before, inside, after, nil
+}
+
+func withRange(rule pdclient.Rule, start, end []byte) pdclient.Rule {
+	rule.StartKey = start
+	rule.StartKeyHex = hex.EncodeToString(start)
+	rule.EndKey = end
+	rule.EndKeyHex = hex.EncodeToString(end)
+	return rule
+}
@@ -0,0 +1,105 @@
+package keyrange
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+func hexRule(id, start, end string) pdclient.Rule {
+	return pdclient.Rule{GroupID: "tiflash", ID: id, StartKeyHex: start, EndKeyHex: end, Count: 1}
+}
+
+func TestSplitOverlapping(t *testing.T) {
+	// Rule spans three keyspaces' worth of key space; ks covers the
+	// middle third.
+	rule := hexRule("r1", "78000000", "78000010")
+	ksStart, ksEnd := "78000001", "78000002"
+
+	before, inside, after, err := Split(rule, mustHex(ksStart), mustHex(ksEnd))
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if before == nil || before.StartKeyHex != "78000000" || before.EndKeyHex != "78000001" {
+		t.Errorf("before = %+v, want [78000000,78000001)", before)
+	}
+	if inside == nil || inside.StartKeyHex != "78000001" || inside.EndKeyHex != "78000002" {
+		t.Errorf("inside = %+v, want [78000001,78000002)", inside)
+	}
+	if after == nil || after.StartKeyHex != "78000002" || after.EndKeyHex != "78000010" {
+		t.Errorf("after = %+v, want [78000002,78000010)", after)
+	}
+	if inside.ID != "r1" {
+		t.Errorf("inside.ID = %q, want unchanged %q", inside.ID, "r1")
+	}
+	if before.ID != "r1-before" || after.ID != "r1-after" {
+		t.Errorf("before.ID=%q after.ID=%q, want r1-before/r1-after", before.ID, after.ID)
+	}
+}
+
+// TestSplitDisjointBefore covers the bug where a rule entirely before the
+// target keyspace got its "before" portion stretched out to ksStart
+// instead of staying within the rule's own end, silently claiming
+// whatever keyspaces lie in between.
+func TestSplitDisjointBefore(t *testing.T) {
+	rule := hexRule("r1", "78000001", "78000002") // keyspace 1's own range
+	ksStart, ksEnd := "78000005", "78000006"      // keyspace 5, far away
+
+	before, inside, after, err := Split(rule, mustHex(ksStart), mustHex(ksEnd))
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if inside != nil {
+		t.Errorf("inside = %+v, want nil (disjoint ranges)", inside)
+	}
+	if after != nil {
+		t.Errorf("after = %+v, want nil (disjoint ranges)", after)
+	}
+	if before == nil || before.StartKeyHex != "78000001" || before.EndKeyHex != "78000002" {
+		t.Fatalf("before = %+v, want the original unchanged range [78000001,78000002)", before)
+	}
+	if before.ID != "r1" {
+		t.Errorf("before.ID = %q, want unchanged %q: a rule that was never split must keep its identity", before.ID, "r1")
+	}
+}
+
+// TestSplitDisjointAfter is the mirror image: a rule entirely after the
+// target keyspace must pass through unchanged as "after", not get its
+// start pulled back to ksEnd.
+func TestSplitDisjointAfter(t *testing.T) {
+	rule := hexRule("r1", "78000010", "78000012")
+	ksStart, ksEnd := "78000005", "78000006"
+
+	before, inside, after, err := Split(rule, mustHex(ksStart), mustHex(ksEnd))
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if before != nil {
+		t.Errorf("before = %+v, want nil (disjoint ranges)", before)
+	}
+	if inside != nil {
+		t.Errorf("inside = %+v, want nil (disjoint ranges)", inside)
+	}
+	if after == nil || after.StartKeyHex != "78000010" || after.EndKeyHex != "78000012" {
+		t.Fatalf("after = %+v, want the original unchanged range [78000010,78000012)", after)
+	}
+	if after.ID != "r1" {
+		t.Errorf("after.ID = %q, want unchanged %q: a rule that was never split must keep its identity", after.ID, "r1")
+	}
+}
+
+func TestRange(t *testing.T) {
+	start, end := Range(1)
+	if hex.EncodeToString(start) != "78000001" || hex.EncodeToString(end) != "78000002" {
+		t.Errorf("Range(1) = [%x,%x), want [78000001,78000002)", start, end)
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
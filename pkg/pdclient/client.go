@@ -0,0 +1,165 @@
+package pdclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	rulesPath      = "/pd/api/v1/config/placement-rule"
+	rulesBatchPath = "/pd/api/v1/config/placement-rule" // same endpoint accepts a batch of RuleOp/GroupOp
+)
+
+// Config describes how to reach a PD cluster.
+type Config struct {
+	Addr      string
+	TLSConfig *tls.Config
+}
+
+// Client is a thin wrapper around PD's placement-rule HTTP API.
+type Client struct {
+	addr string
+	cli  *http.Client
+}
+
+// NewClient builds a Client that talks to cfg.Addr, optionally over TLS.
+func NewClient(cfg Config) *Client {
+	transport := &http.Transport{}
+	if cfg.TLSConfig != nil {
+		transport.TLSClientConfig = cfg.TLSConfig
+	}
+	return &Client{
+		addr: cfg.Addr,
+		cli:  &http.Client{Transport: transport},
+	}
+}
+
+// GetRules fetches the full current rule set from PD.
+func (c *Client) GetRules(ctx context.Context) (Rules, error) {
+	url := fmt.Sprintf("%s%s", c.addr, rulesPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch rules from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch rules from %s: status %s: %s", url, resp.Status, body)
+	}
+	var rules Rules
+	if err := json.Unmarshal(body, &rules); err != nil {
+		return nil, fmt.Errorf("unmarshal rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Batch is a single transactional update: a set of rule ops and group ops
+// applied together, mirroring how PD itself commits placement-rule changes
+// in one txn.
+type Batch struct {
+	RuleOps  []RuleOp  `json:"rules,omitempty"`
+	GroupOps []GroupOp `json:"groups,omitempty"`
+}
+
+// Empty reports whether the batch has nothing to apply.
+func (b Batch) Empty() bool {
+	return len(b.RuleOps) == 0 && len(b.GroupOps) == 0
+}
+
+// ApplyBatch pushes ruleOps and groupOps to PD's batch placement-rule
+// endpoint so they land as a single atomic update. When dryRun is true, no
+// request is sent.
+func (c *Client) ApplyBatch(ctx context.Context, batch Batch, dryRun bool) error {
+	if batch.Empty() {
+		return nil
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("marshal batch: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+	url := fmt.Sprintf("%s%s", c.addr, rulesBatchPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return fmt.Errorf("apply batch to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("apply batch to %s: status %s: %s", url, resp.Status, body)
+	}
+	return nil
+}
+
+// Diff computes the RuleOps/GroupOps needed to move from cur to want,
+// keyed on (GroupID, ID) for rules and ID for groups. Rules present in
+// want but not cur are added; rules present in cur but not want are
+// removed; rules present in both but changed are replaced in place.
+func Diff(cur, want Rules, wantGroup *RuleGroup) Batch {
+	curByKey := make(map[[2]string]Rule, len(cur))
+	for _, r := range cur {
+		curByKey[[2]string{r.GroupID, r.ID}] = r
+	}
+	wantByKey := make(map[[2]string]Rule, len(want))
+	for _, r := range want {
+		wantByKey[[2]string{r.GroupID, r.ID}] = r
+	}
+
+	var batch Batch
+	for key, r := range wantByKey {
+		old, ok := curByKey[key]
+		if !ok || !ruleEqual(old, r) {
+			batch.RuleOps = append(batch.RuleOps, RuleOp{Rule: r, Action: RuleOpAdd})
+		}
+	}
+	for key, r := range curByKey {
+		if _, ok := wantByKey[key]; !ok {
+			batch.RuleOps = append(batch.RuleOps, RuleOp{Rule: r, Action: RuleOpDel})
+		}
+	}
+	if wantGroup != nil {
+		batch.GroupOps = append(batch.GroupOps, GroupOp{RuleGroup: *wantGroup, Action: RuleOpAdd})
+	}
+	return batch
+}
+
+func ruleEqual(a, b Rule) bool {
+	data1, _ := json.Marshal(a)
+	data2, _ := json.Marshal(b)
+	return bytes.Equal(data1, data2)
+}
+
+// PrintDiff prints the additions/removals a batch represents, for
+// --diff mode.
+func PrintDiff(w io.Writer, batch Batch) {
+	for _, op := range batch.RuleOps {
+		switch op.Action {
+		case RuleOpAdd:
+			fmt.Fprintf(w, "+ rule %s/%s\n", op.GroupID, op.ID)
+		case RuleOpDel:
+			fmt.Fprintf(w, "- rule %s/%s\n", op.GroupID, op.ID)
+		}
+	}
+	for _, op := range batch.GroupOps {
+		fmt.Fprintf(w, "~ group %s\n", op.ID)
+	}
+}
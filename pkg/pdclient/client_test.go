@@ -0,0 +1,69 @@
+package pdclient
+
+import "testing"
+
+func TestDiffAddsRemovesAndReplaces(t *testing.T) {
+	cur := Rules{
+		{GroupID: "tiflash", ID: "keep", Count: 1},
+		{GroupID: "tiflash", ID: "stale", Count: 1},
+		{GroupID: "tiflash", ID: "changed", Count: 1},
+	}
+	want := Rules{
+		{GroupID: "tiflash", ID: "keep", Count: 1},
+		{GroupID: "tiflash", ID: "changed", Count: 2},
+		{GroupID: "enable_s3_wn_region", ID: "new", Count: 1},
+	}
+
+	batch := Diff(cur, want, &RuleGroup{ID: "enable_s3_wn_region", Index: 1})
+
+	var adds, dels int
+	var sawNew, sawChanged, sawStaleDel bool
+	for _, op := range batch.RuleOps {
+		switch op.Action {
+		case RuleOpAdd:
+			adds++
+			if op.ID == "new" {
+				sawNew = true
+			}
+			if op.ID == "changed" && op.Count != 2 {
+				t.Errorf("changed rule should carry the new count, got %d", op.Count)
+			}
+			if op.ID == "changed" {
+				sawChanged = true
+			}
+		case RuleOpDel:
+			dels++
+			if op.ID == "stale" {
+				sawStaleDel = true
+			}
+		}
+	}
+	if !sawNew {
+		t.Error("expected an add op for the new rule")
+	}
+	if !sawChanged {
+		t.Error("expected an add op replacing the changed rule")
+	}
+	if !sawStaleDel {
+		t.Error("expected a del op for the rule missing from want")
+	}
+	// "keep" is identical in cur and want, so it should not appear at all.
+	if adds != 2 {
+		t.Errorf("got %d add ops, want 2 (new, changed)", adds)
+	}
+	if dels != 1 {
+		t.Errorf("got %d del ops, want 1 (stale)", dels)
+	}
+
+	if len(batch.GroupOps) != 1 || batch.GroupOps[0].ID != "enable_s3_wn_region" {
+		t.Errorf("group ops = %+v, want one enable_s3_wn_region op", batch.GroupOps)
+	}
+}
+
+func TestDiffEmptyWhenUnchanged(t *testing.T) {
+	rules := Rules{{GroupID: "tiflash", ID: "r1", Count: 1}}
+	batch := Diff(rules, rules, nil)
+	if !batch.Empty() {
+		t.Errorf("batch = %+v, want empty", batch)
+	}
+}
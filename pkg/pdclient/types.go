@@ -1,17 +1,14 @@
-package main
+// Package pdclient talks to PD's placement-rule HTTP API: fetching the
+// current rule set, and pushing back a batch of rule/group changes as a
+// single transactional update.
+package pdclient
 
-import (
-	"fmt"
-	"encoding/json"
-	"os"
-)
-
-type Rules []Rule
 type RuleGroup struct {
 	ID       string `json:"id,omitempty"`
 	Index    int    `json:"index,omitempty"`
 	Override bool   `json:"override,omitempty"`
 }
+
 type LabelConstraintOp string
 
 const (
@@ -32,6 +29,7 @@ type LabelConstraint struct {
 	Op     LabelConstraintOp `json:"op,omitempty"`
 	Values []string          `json:"values,omitempty"`
 }
+
 type PeerRoleType string
 
 const (
@@ -60,47 +58,39 @@ type Rule struct {
 	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"` // used to select stores to place peers
 	LocationLabels   []string          `json:"location_labels,omitempty"`   // used to make peers isolated physically
 	IsolationLevel   string            `json:"isolation_level,omitempty"`   // used to isolate replicas explicitly and forcibly
-	Version          uint64            `json:"-"`           // only set at runtime, add 1 each time rules updated, begin from 0.
-	CreateTimestamp  uint64            `json:"-"`  // only set at runtime, recorded rule create timestamp
-	group            *RuleGroup        // only set at runtime, no need to {,un}marshal or persist.
+	Version          uint64            `json:"-"`                           // only set at runtime, add 1 each time rules updated, begin from 0.
+	CreateTimestamp  uint64            `json:"-"`                           // only set at runtime, recorded rule create timestamp
 }
 
+type Rules []Rule
+
 const (
-	LabelKeyEngineRole = "engine_role"
+	LabelKeyEngineRole        = "engine_role"
 	LabelValueEngineRoleWrite = "write"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		panic(fmt.Sprintf("Usage: %v cur_rules.json", os.Args[0]))
-	}
-	data, err := os.ReadFile(os.Args[1])
-	if err != nil {
-		panic(err)
-	}
+// RuleOpType is the action a RuleOp applies to PD when POSTed to the batch
+// placement-rule endpoint.
+type RuleOpType string
 
-	var curRules Rules
-	if err := json.Unmarshal(data, &curRules); err != nil {
-		panic(err)
-	}
+const (
+	// RuleOpAdd inserts or replaces a rule.
+	RuleOpAdd RuleOpType = "add"
+	// RuleOpDel removes a rule.
+	RuleOpDel RuleOpType = "del"
+)
+
+// RuleOp is one entry of a batch placement-rule update: either add/replace
+// a rule, or delete one identified by (GroupID, ID).
+type RuleOp struct {
+	Rule
+	Action           RuleOpType `json:"action"`
+	DeleteByIDPrefix bool       `json:"delete_by_id_prefix,omitempty"`
+}
 
-	for _, rule := range curRules {
-		if rule.GroupID != "tiflash" {
-			panic("got rule that are not tiflash group")
-		}
-		var alreadyDisableWriteRole bool
-		for _, con := range rule.LabelConstraints {
-			if con.Key == LabelKeyEngineRole {
-				if con.Op != NotIn || len(con.Values) != 1 || con.Values[0] != LabelValueEngineRoleWrite {
-					panic(fmt.Sprintf("invalid rule: %v", rule))
-				}
-				alreadyDisableWriteRole = true
-				break
-			}
-		}
-		if !alreadyDisableWriteRole {
-			panic(fmt.Sprintf("this rule doesn't disable wn, ignore: %v", rule))
-		}
-	}
-	fmt.Println("check done, all rules has engine_role constraints")
+// GroupOp is one entry of a batch rule-group update: either upsert or
+// delete a RuleGroup.
+type GroupOp struct {
+	RuleGroup
+	Action RuleOpType `json:"action"`
 }
@@ -0,0 +1,90 @@
+// Package rollback diffs two rule dumps and produces the pdclient.Batch
+// that reverts the second back to the first, for undoing a migration
+// (e.g. an enable_s3_wn_region rollout) without hand-editing JSON.
+package rollback
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+// Dump is the on-disk shape rollback reads: a rules array plus, when the
+// migration also created/changed rule groups, the groups it touched. A
+// bare JSON array (the format check.go/enable.go/delete.go produced) is
+// also accepted as a Dump with no Groups.
+type Dump struct {
+	Groups []pdclient.RuleGroup `json:"groups,omitempty"`
+	Rules  pdclient.Rules       `json:"rules"`
+}
+
+// ParseDump decodes a rollback input file, accepting either the
+// {"groups":...,"rules":...} shape or a bare rules array.
+func ParseDump(data []byte) (Dump, error) {
+	var d Dump
+	if err := json.Unmarshal(data, &d); err == nil {
+		return d, nil
+	}
+	var rules pdclient.Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Dump{}, fmt.Errorf("parse dump: %w", err)
+	}
+	return Dump{Rules: rules}, nil
+}
+
+// Generate diffs before and after, keyed on (GroupID,ID) for rules and ID
+// for groups, and returns the batch that restores before's state: rules
+// added in after are deleted, rules removed in after are re-added, rules
+// changed in after are replaced with before's version, and likewise for
+// groups. Since before/after both decode into the canonical pdclient.Rule
+// (consistent json tags across all fields), no extra field normalization
+// is needed before the reflect.DeepEqual comparison.
+func Generate(before, after Dump) pdclient.Batch {
+	var batch pdclient.Batch
+
+	beforeRules := indexRules(before.Rules)
+	afterRules := indexRules(after.Rules)
+	for key, r := range afterRules {
+		if _, ok := beforeRules[key]; !ok {
+			batch.RuleOps = append(batch.RuleOps, pdclient.RuleOp{Rule: r, Action: pdclient.RuleOpDel})
+		}
+	}
+	for key, r := range beforeRules {
+		if ar, ok := afterRules[key]; !ok || !reflect.DeepEqual(r, ar) {
+			batch.RuleOps = append(batch.RuleOps, pdclient.RuleOp{Rule: r, Action: pdclient.RuleOpAdd})
+		}
+	}
+
+	beforeGroups := indexGroups(before.Groups)
+	afterGroups := indexGroups(after.Groups)
+	for id, g := range afterGroups {
+		if _, ok := beforeGroups[id]; !ok {
+			batch.GroupOps = append(batch.GroupOps, pdclient.GroupOp{RuleGroup: g, Action: pdclient.RuleOpDel})
+		}
+	}
+	for id, g := range beforeGroups {
+		if ag, ok := afterGroups[id]; !ok || !reflect.DeepEqual(g, ag) {
+			batch.GroupOps = append(batch.GroupOps, pdclient.GroupOp{RuleGroup: g, Action: pdclient.RuleOpAdd})
+		}
+	}
+
+	return batch
+}
+
+func indexRules(rules pdclient.Rules) map[[2]string]pdclient.Rule {
+	out := make(map[[2]string]pdclient.Rule, len(rules))
+	for _, r := range rules {
+		out[[2]string{r.GroupID, r.ID}] = r
+	}
+	return out
+}
+
+func indexGroups(groups []pdclient.RuleGroup) map[string]pdclient.RuleGroup {
+	out := make(map[string]pdclient.RuleGroup, len(groups))
+	for _, g := range groups {
+		out[g.ID] = g
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package rollback
+
+import (
+	"testing"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+func TestGenerateInvertsAddsRemovesAndChanges(t *testing.T) {
+	before := Dump{
+		Rules: pdclient.Rules{
+			{GroupID: "tiflash", ID: "removed-by-migration", Count: 1},
+			{GroupID: "tiflash", ID: "changed", Count: 1},
+		},
+	}
+	after := Dump{
+		Groups: []pdclient.RuleGroup{{ID: "enable_s3_wn_region", Index: 1}},
+		Rules: pdclient.Rules{
+			{GroupID: "tiflash", ID: "changed", Count: 2},
+			{GroupID: "enable_s3_wn_region", ID: "added-by-migration", Count: 1},
+		},
+	}
+
+	batch := Generate(before, after)
+
+	var adds, dels map[string]bool = map[string]bool{}, map[string]bool{}
+	for _, op := range batch.RuleOps {
+		switch op.Action {
+		case pdclient.RuleOpAdd:
+			adds[op.ID] = true
+		case pdclient.RuleOpDel:
+			dels[op.ID] = true
+		}
+	}
+
+	if !dels["added-by-migration"] {
+		t.Error("want a del op undoing the rule the migration added")
+	}
+	if !adds["removed-by-migration"] {
+		t.Error("want an add op restoring the rule the migration removed")
+	}
+	if !adds["changed"] {
+		t.Error("want an add op reverting the rule the migration changed")
+	}
+	for _, op := range batch.RuleOps {
+		if op.ID == "changed" && op.Action == pdclient.RuleOpAdd && op.Count != 1 {
+			t.Errorf("reverted 'changed' rule should carry before's count, got %d", op.Count)
+		}
+	}
+
+	if len(batch.GroupOps) != 1 || batch.GroupOps[0].ID != "enable_s3_wn_region" || batch.GroupOps[0].Action != pdclient.RuleOpDel {
+		t.Errorf("group ops = %+v, want a single del of enable_s3_wn_region", batch.GroupOps)
+	}
+}
+
+func TestGenerateNoopWhenUnchanged(t *testing.T) {
+	dump := Dump{Rules: pdclient.Rules{{GroupID: "tiflash", ID: "r1", Count: 1}}}
+	batch := Generate(dump, dump)
+	if !batch.Empty() {
+		t.Errorf("batch = %+v, want empty", batch)
+	}
+}
+
+func TestParseDumpAcceptsBareArray(t *testing.T) {
+	d, err := ParseDump([]byte(`[{"group_id":"tiflash","id":"r1","count":1}]`))
+	if err != nil {
+		t.Fatalf("ParseDump: %v", err)
+	}
+	if len(d.Rules) != 1 || d.Rules[0].ID != "r1" {
+		t.Errorf("rules = %+v", d.Rules)
+	}
+	if len(d.Groups) != 0 {
+		t.Errorf("groups = %+v, want none", d.Groups)
+	}
+}
+
+func TestParseDumpAcceptsWrapped(t *testing.T) {
+	d, err := ParseDump([]byte(`{"groups":[{"id":"g1"}],"rules":[{"group_id":"tiflash","id":"r1","count":1}]}`))
+	if err != nil {
+		t.Fatalf("ParseDump: %v", err)
+	}
+	if len(d.Groups) != 1 || d.Groups[0].ID != "g1" {
+		t.Errorf("groups = %+v", d.Groups)
+	}
+	if len(d.Rules) != 1 || d.Rules[0].ID != "r1" {
+		t.Errorf("rules = %+v", d.Rules)
+	}
+}
@@ -0,0 +1,116 @@
+package ruleplan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guo-shaoge/change_rules/pkg/keyrange"
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+// Compile applies plan to every rule in cur and returns the rewritten
+// set. Rules matched by no RuleSpec are dropped, mirroring how the
+// standalone enable.go/delete.go mains only emitted the rules they
+// transformed.
+func Compile(plan *Plan, cur pdclient.Rules) (pdclient.Rules, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	var out pdclient.Rules
+	for _, rule := range cur {
+		for _, rs := range plan.Rules {
+			if !matches(rs.Match, rule) {
+				continue
+			}
+			matched, err := splitAndApply(rs, rule)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, matched...)
+			break
+		}
+	}
+	return out, nil
+}
+
+// splitAndApply applies rs to rule, first narrowing rule's key range to
+// rs.SplitKeyspace (if set) so neighboring keyspaces sharing the rule
+// pass through untouched.
+func splitAndApply(rs RuleSpec, rule pdclient.Rule) (pdclient.Rules, error) {
+	if rs.SplitKeyspace == nil {
+		return pdclient.Rules{apply(rs, rule)}, nil
+	}
+
+	ksStart, ksEnd := keyrange.Range(*rs.SplitKeyspace)
+	before, inside, after, err := keyrange.Split(rule, ksStart, ksEnd)
+	if err != nil {
+		return nil, fmt.Errorf("split %s/%s by keyspace %d: %w", rule.GroupID, rule.ID, *rs.SplitKeyspace, err)
+	}
+
+	var out pdclient.Rules
+	if before != nil {
+		out = append(out, *before)
+	}
+	if inside != nil {
+		out = append(out, apply(rs, *inside))
+	}
+	if after != nil {
+		out = append(out, *after)
+	}
+	return out, nil
+}
+
+func matches(m MatchSpec, rule pdclient.Rule) bool {
+	if m.GroupID != "" && rule.GroupID != m.GroupID {
+		return false
+	}
+	if m.IDContains != "" && !strings.Contains(rule.ID, m.IDContains) {
+		return false
+	}
+	if m.HasConstraint != nil {
+		var found bool
+		for _, con := range rule.LabelConstraints {
+			if con.Key == m.HasConstraint.Key && string(con.Op) == m.HasConstraint.Op {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func apply(rs RuleSpec, rule pdclient.Rule) pdclient.Rule {
+	if rs.Set.GroupID != "" {
+		rule.GroupID = rs.Set.GroupID
+	}
+	if rs.Set.Index != nil {
+		rule.Index = *rs.Set.Index
+	}
+	if rs.Set.Count != nil {
+		rule.Count = *rs.Set.Count
+	}
+	for _, key := range rs.RemoveConstraint {
+		rule.LabelConstraints = removeConstraint(rule.LabelConstraints, key)
+	}
+	for _, c := range rs.AddConstraint {
+		rule.LabelConstraints = append(rule.LabelConstraints, pdclient.LabelConstraint{
+			Key:    c.Key,
+			Op:     pdclient.LabelConstraintOp(c.Op),
+			Values: c.Values,
+		})
+	}
+	return rule
+}
+
+func removeConstraint(cons []pdclient.LabelConstraint, key string) []pdclient.LabelConstraint {
+	var out []pdclient.LabelConstraint
+	for _, c := range cons {
+		if c.Key != key {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package ruleplan
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/guo-shaoge/change_rules/pkg/keyrange"
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+func TestCompileSetAndConstraints(t *testing.T) {
+	plan := &Plan{
+		Rules: []RuleSpec{
+			{
+				Match:            MatchSpec{GroupID: "tiflash"},
+				Set:              SetSpec{GroupID: "enable_s3_wn_region"},
+				AddConstraint:    []LabelConstraintSpec{{Key: "engine", Op: "in", Values: []string{"tiflash"}}},
+				RemoveConstraint: []string{"zone"},
+			},
+		},
+	}
+	cur := pdclient.Rules{
+		{GroupID: "tiflash", ID: "r1", Count: 1, LabelConstraints: []pdclient.LabelConstraint{{Key: "zone", Op: pdclient.In, Values: []string{"z1"}}}},
+	}
+
+	out, err := Compile(plan, cur)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("out = %+v, want 1 rule", out)
+	}
+	r := out[0]
+	if r.GroupID != "enable_s3_wn_region" {
+		t.Errorf("GroupID = %q, want enable_s3_wn_region", r.GroupID)
+	}
+	if len(r.LabelConstraints) != 1 || r.LabelConstraints[0].Key != "engine" {
+		t.Errorf("LabelConstraints = %+v, want only the added engine constraint", r.LabelConstraints)
+	}
+}
+
+func TestCompileSplitKeyspacePartialOverlap(t *testing.T) {
+	ks := uint32(1)
+	plan := &Plan{
+		Rules: []RuleSpec{
+			{
+				Match:         MatchSpec{GroupID: "tiflash"},
+				Set:           SetSpec{GroupID: "enable_s3_wn_region"},
+				SplitKeyspace: &ks,
+			},
+		},
+	}
+	// Rule spans keyspaces 0-2; keyspace 1 is its middle third.
+	start, _ := keyrange.Range(0)
+	_, end := keyrange.Range(2)
+	cur := pdclient.Rules{
+		{GroupID: "tiflash", ID: "spanning", Count: 1, StartKey: start, StartKeyHex: hexOf(start), EndKey: end, EndKeyHex: hexOf(end)},
+	}
+
+	out, err := Compile(plan, cur)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("out = %+v, want 3 rules (before/inside/after)", out)
+	}
+
+	ksStart, ksEnd := keyrange.Range(ks)
+	byID := make(map[string]pdclient.Rule, len(out))
+	for _, r := range out {
+		byID[r.ID] = r
+	}
+
+	before, ok := byID["spanning-before"]
+	if !ok || before.GroupID != "tiflash" || before.StartKeyHex != hexOf(start) || before.EndKeyHex != hexOf(ksStart) {
+		t.Errorf("before = %+v, ok=%v, want untouched tiflash rule [%x,%x)", before, ok, start, ksStart)
+	}
+	inside, ok := byID["spanning"]
+	if !ok || inside.GroupID != "enable_s3_wn_region" || inside.StartKeyHex != hexOf(ksStart) || inside.EndKeyHex != hexOf(ksEnd) {
+		t.Errorf("inside = %+v, ok=%v, want rewritten rule [%x,%x)", inside, ok, ksStart, ksEnd)
+	}
+	after, ok := byID["spanning-after"]
+	if !ok || after.GroupID != "tiflash" || after.StartKeyHex != hexOf(ksEnd) || after.EndKeyHex != hexOf(end) {
+		t.Errorf("after = %+v, ok=%v, want untouched tiflash rule [%x,%x)", after, ok, ksEnd, end)
+	}
+}
+
+// TestCompileSplitKeyspaceDisjointPassesThroughUnchanged guards against the
+// bug where a rule entirely outside the target keyspace came back with a
+// -before/-after suffix despite never actually being split: since
+// pdclient.Diff/rollback.Generate key on (GroupID,ID), that silent rename
+// would make an untouched neighboring-keyspace rule look like a
+// delete-and-recreate.
+func TestCompileSplitKeyspaceDisjointPassesThroughUnchanged(t *testing.T) {
+	ks := uint32(1)
+	plan := &Plan{
+		Rules: []RuleSpec{
+			{
+				Match:         MatchSpec{GroupID: "tiflash"},
+				Set:           SetSpec{GroupID: "enable_s3_wn_region"},
+				SplitKeyspace: &ks,
+			},
+		},
+	}
+	start, end := keyrange.Range(5)
+	cur := pdclient.Rules{
+		{GroupID: "tiflash", ID: "keyspace-5-rule", Count: 1, StartKey: start, StartKeyHex: hexOf(start), EndKey: end, EndKeyHex: hexOf(end)},
+	}
+
+	out, err := Compile(plan, cur)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("out = %+v, want the single untouched rule", out)
+	}
+	r := out[0]
+	if r.ID != "keyspace-5-rule" {
+		t.Errorf("ID = %q, want unchanged %q: rule was never split so its identity must not change", r.ID, "keyspace-5-rule")
+	}
+	if r.GroupID != "tiflash" {
+		t.Errorf("GroupID = %q, want unchanged %q: a disjoint rule must pass through untouched, not be rewritten", r.GroupID, "tiflash")
+	}
+}
+
+func hexOf(b []byte) string {
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,210 @@
+// Package ruleplan parses a small declarative spec for rewriting PD
+// placement rules, so new migrations (e.g. "move keyspace-X's tiflash
+// rules onto enable_s3_wn_region") can be expressed in YAML instead of a
+// new main.go. A Plan is a list of match/action pairs, evaluated in order
+// against every input rule: the first matching RuleSpec wins.
+package ruleplan
+
+import (
+	"fmt"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+// Plan is the top-level document: a list of rewrite rules applied in
+// order to each input Rule.
+type Plan struct {
+	Rules []RuleSpec // "rules"
+}
+
+// MatchSpec selects the input rules a RuleSpec applies to. A zero-value
+// field is not checked, so `match: {group_id: tiflash}` matches every
+// tiflash rule regardless of ID or constraints.
+type MatchSpec struct {
+	GroupID       string               // "group_id"
+	IDContains    string               // "id_contains"
+	HasConstraint *LabelConstraintSpec // "has_constraint"
+}
+
+// SetSpec overwrites fields on matched rules. Only non-zero fields are
+// applied; use set.count: 0 to explicitly zero a field (rare).
+type SetSpec struct {
+	GroupID string // "group_id"
+	Index   *int   // "index"
+	Count   *int   // "count"
+}
+
+// LabelConstraintSpec is the YAML form of pdclient.LabelConstraint.
+type LabelConstraintSpec struct {
+	Key    string   // "key"
+	Op     string   // "op"
+	Values []string // "values"
+}
+
+// RuleSpec is one match/action pair: every input rule satisfying Match is
+// rewritten by Set/AddConstraint/RemoveConstraint, in that order.
+type RuleSpec struct {
+	Match            MatchSpec             // "match"
+	Set              SetSpec               // "set"
+	AddConstraint    []LabelConstraintSpec // "add_constraint"
+	RemoveConstraint []string              // "remove_constraint": label keys to drop
+
+	// SplitKeyspace, when set, intersects each matched rule's key range
+	// with this keyspace's canonical range before Set/AddConstraint are
+	// applied. The portions of the rule outside the keyspace pass through
+	// unchanged (same ID), so a rule shared by several keyspaces isn't
+	// rewritten wholesale; only when the rule is actually split into
+	// multiple pieces do the non-matching pieces get a -before/-after
+	// suffix to avoid colliding with the rewritten piece's ID.
+	SplitKeyspace *uint32 // "split_keyspace"
+}
+
+// Parse decodes a YAML plan document.
+func Parse(data []byte) (*Plan, error) {
+	root, err := parseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	rootMap, _ := asMap(root)
+	plan, err := decodePlan(rootMap)
+	if err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	return plan, nil
+}
+
+func decodePlan(root map[string]interface{}) (*Plan, error) {
+	var plan Plan
+	rawRules, _ := asSlice(root["rules"])
+	for i, raw := range rawRules {
+		ruleMap, ok := asMap(raw)
+		if !ok {
+			return nil, fmt.Errorf("rules[%d]: expected a mapping", i)
+		}
+		rs, err := decodeRuleSpec(ruleMap)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		plan.Rules = append(plan.Rules, rs)
+	}
+	return &plan, nil
+}
+
+func decodeRuleSpec(m map[string]interface{}) (RuleSpec, error) {
+	var rs RuleSpec
+
+	if matchMap, ok := asMap(m["match"]); ok {
+		rs.Match.GroupID = asString(matchMap["group_id"])
+		rs.Match.IDContains = asString(matchMap["id_contains"])
+		if hc, ok := asMap(matchMap["has_constraint"]); ok {
+			rs.Match.HasConstraint = &LabelConstraintSpec{
+				Key: asString(hc["key"]),
+				Op:  asString(hc["op"]),
+			}
+		}
+	}
+
+	if setMap, ok := asMap(m["set"]); ok {
+		rs.Set.GroupID = asString(setMap["group_id"])
+		if n, ok := asInt(setMap["index"]); ok {
+			rs.Set.Index = &n
+		}
+		if n, ok := asInt(setMap["count"]); ok {
+			rs.Set.Count = &n
+		}
+	}
+
+	addConstraint, _ := asSlice(m["add_constraint"])
+	for _, raw := range addConstraint {
+		cm, ok := asMap(raw)
+		if !ok {
+			return rs, fmt.Errorf("add_constraint: expected a mapping")
+		}
+		rs.AddConstraint = append(rs.AddConstraint, LabelConstraintSpec{
+			Key:    asString(cm["key"]),
+			Op:     asString(cm["op"]),
+			Values: asStringSlice(cm["values"]),
+		})
+	}
+
+	removeConstraint, _ := asSlice(m["remove_constraint"])
+	for _, raw := range removeConstraint {
+		rs.RemoveConstraint = append(rs.RemoveConstraint, asString(raw))
+	}
+
+	if n, ok := asInt(m["split_keyspace"]); ok {
+		ks := uint32(n)
+		rs.SplitKeyspace = &ks
+	}
+
+	return rs, nil
+}
+
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	return m, ok
+}
+
+func asSlice(v interface{}) ([]interface{}, bool) {
+	s, ok := v.([]interface{})
+	return s, ok
+}
+
+func asString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func asInt(v interface{}) (int, bool) {
+	n, ok := v.(int)
+	return n, ok
+}
+
+func asStringSlice(v interface{}) []string {
+	raw, ok := asSlice(v)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, r := range raw {
+		out = append(out, asString(r))
+	}
+	return out
+}
+
+// Validate checks the plan against the schema described in the backlog:
+// Op must be in|notIn|exists|notExists, Count>0 when set, and GroupID
+// non-empty when set.
+func (p *Plan) Validate() error {
+	for i, rs := range p.Rules {
+		if rs.Set.GroupID == "" && rs.Match.GroupID == "" && rs.Match.IDContains == "" && rs.Match.HasConstraint == nil {
+			return fmt.Errorf("rules[%d]: match must select on at least one of group_id/id_contains/has_constraint", i)
+		}
+		if rs.Set.Count != nil && *rs.Set.Count <= 0 {
+			return fmt.Errorf("rules[%d].set.count: must be > 0, got %d", i, *rs.Set.Count)
+		}
+		for j, c := range rs.AddConstraint {
+			if err := validateOp(c.Op); err != nil {
+				return fmt.Errorf("rules[%d].add_constraint[%d]: %w", i, j, err)
+			}
+			if c.Key == "" {
+				return fmt.Errorf("rules[%d].add_constraint[%d]: key must not be empty", i, j)
+			}
+		}
+		if rs.Match.HasConstraint != nil {
+			if err := validateOp(rs.Match.HasConstraint.Op); err != nil {
+				return fmt.Errorf("rules[%d].match.has_constraint: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateOp(op string) error {
+	switch pdclient.LabelConstraintOp(op) {
+	case pdclient.In, pdclient.NotIn, pdclient.Exists, pdclient.NotExists:
+		return nil
+	default:
+		return fmt.Errorf("op must be one of in|notIn|exists|notExists, got %q", op)
+	}
+}
@@ -0,0 +1,74 @@
+package ruleplan
+
+import "testing"
+
+const examplePlan = `
+rules:
+  - match:
+      group_id: tiflash
+      id_contains: "keyspace-1-"
+    split_keyspace: 1
+    set:
+      group_id: enable_s3_wn_region
+      index: 1
+      count: 1
+    remove_constraint:
+      - engine_role
+    add_constraint:
+      - key: engine_role
+        op: in
+        values: ["write"]
+      - key: engine
+        op: in
+        values: ["tiflash"]
+`
+
+func TestParseExamplePlan(t *testing.T) {
+	plan, err := Parse([]byte(examplePlan))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(plan.Rules) != 1 {
+		t.Fatalf("got %d rules, want 1", len(plan.Rules))
+	}
+	rs := plan.Rules[0]
+
+	if rs.Match.GroupID != "tiflash" || rs.Match.IDContains != "keyspace-1-" {
+		t.Errorf("match = %+v", rs.Match)
+	}
+	if rs.SplitKeyspace == nil || *rs.SplitKeyspace != 1 {
+		t.Errorf("split_keyspace = %v, want 1", rs.SplitKeyspace)
+	}
+	if rs.Set.GroupID != "enable_s3_wn_region" || rs.Set.Index == nil || *rs.Set.Index != 1 || rs.Set.Count == nil || *rs.Set.Count != 1 {
+		t.Errorf("set = %+v", rs.Set)
+	}
+	if len(rs.RemoveConstraint) != 1 || rs.RemoveConstraint[0] != "engine_role" {
+		t.Errorf("remove_constraint = %v", rs.RemoveConstraint)
+	}
+	if len(rs.AddConstraint) != 2 {
+		t.Fatalf("got %d add_constraint entries, want 2", len(rs.AddConstraint))
+	}
+	if rs.AddConstraint[0].Key != "engine_role" || rs.AddConstraint[0].Op != "in" || len(rs.AddConstraint[0].Values) != 1 || rs.AddConstraint[0].Values[0] != "write" {
+		t.Errorf("add_constraint[0] = %+v", rs.AddConstraint[0])
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestValidateRejectsBadOp(t *testing.T) {
+	plan, err := Parse([]byte(`
+rules:
+  - match:
+      group_id: tiflash
+    add_constraint:
+      - key: engine_role
+        op: bogus
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := plan.Validate(); err == nil {
+		t.Fatal("Validate: want error for unknown op, got nil")
+	}
+}
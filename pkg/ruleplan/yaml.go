@@ -0,0 +1,211 @@
+package ruleplan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file implements just enough of YAML's block style to read a
+// ruleplan document, so the package has no third-party dependency:
+// nested mappings/sequences, "- " sequence markers (including the
+// "- key: value" map-in-sequence shorthand, where the inline key's
+// column becomes the indent for its sibling keys), flow sequences
+// ([a, b]), quoted and bare scalars, and full-line "#" comments. It does
+// not attempt the rest of the YAML spec (anchors, multi-line scalars,
+// tabs, etc.) — ruleplan documents don't need them.
+
+type yamlLine struct {
+	indent int
+	text   string
+}
+
+func tokenizeYAML(data []byte) []yamlLine {
+	var lines []yamlLine
+	for _, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lines = append(lines, yamlLine{indent: len(raw) - len(trimmed), text: trimmed})
+	}
+	return lines
+}
+
+// parseYAML decodes data into nested map[string]interface{},
+// []interface{} and scalar (string/int/bool) values.
+func parseYAML(data []byte) (interface{}, error) {
+	lines := tokenizeYAML(data)
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	pos := 0
+	node, err := parseYAMLNode(lines, &pos, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if pos != len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indent %d", pos+1, lines[pos].indent)
+	}
+	return node, nil
+}
+
+func parseYAMLNode(lines []yamlLine, pos *int, indent int) (interface{}, error) {
+	if *pos >= len(lines) || lines[*pos].indent < indent {
+		return nil, nil
+	}
+	if lines[*pos].text == "-" || strings.HasPrefix(lines[*pos].text, "- ") {
+		return parseYAMLSeq(lines, pos, indent)
+	}
+	return parseYAMLMap(lines, pos, indent)
+}
+
+func parseYAMLSeq(lines []yamlLine, pos *int, indent int) ([]interface{}, error) {
+	var seq []interface{}
+	for *pos < len(lines) && lines[*pos].indent == indent && (lines[*pos].text == "-" || strings.HasPrefix(lines[*pos].text, "- ")) {
+		rest := strings.TrimSpace(strings.TrimPrefix(lines[*pos].text, "-"))
+		*pos++
+		if rest == "" {
+			if *pos < len(lines) && lines[*pos].indent > indent {
+				child, err := parseYAMLNode(lines, pos, lines[*pos].indent)
+				if err != nil {
+					return nil, err
+				}
+				seq = append(seq, child)
+			} else {
+				seq = append(seq, nil)
+			}
+			continue
+		}
+		key, val, hasKey, err := splitYAMLKeyVal(rest)
+		if err != nil {
+			return nil, err
+		}
+		if !hasKey {
+			seq = append(seq, val)
+			continue
+		}
+		// "- key: value" shorthand: the item is a mapping whose first
+		// key starts right after "- ", so its sibling keys line up at
+		// that same column (indent+2).
+		itemIndent := indent + 2
+		item := map[string]interface{}{}
+		if val == nil && *pos < len(lines) && lines[*pos].indent > itemIndent {
+			child, err := parseYAMLNode(lines, pos, lines[*pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			item[key] = child
+		} else {
+			item[key] = val
+		}
+		for *pos < len(lines) && lines[*pos].indent == itemIndent {
+			k, v, hasV, err := splitYAMLKeyVal(lines[*pos].text)
+			if err != nil {
+				return nil, err
+			}
+			if !hasV {
+				*pos++
+				continue
+			}
+			if v == nil && *pos+1 < len(lines) && lines[*pos+1].indent > itemIndent {
+				*pos++
+				child, err := parseYAMLNode(lines, pos, lines[*pos].indent)
+				if err != nil {
+					return nil, err
+				}
+				item[k] = child
+				continue
+			}
+			*pos++
+			item[k] = v
+		}
+		seq = append(seq, item)
+	}
+	return seq, nil
+}
+
+func parseYAMLMap(lines []yamlLine, pos *int, indent int) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	for *pos < len(lines) && lines[*pos].indent == indent && !strings.HasPrefix(lines[*pos].text, "- ") && lines[*pos].text != "-" {
+		key, val, hasKey, err := splitYAMLKeyVal(lines[*pos].text)
+		if err != nil {
+			return nil, err
+		}
+		if !hasKey {
+			return nil, fmt.Errorf("line %d: expected \"key: value\", got %q", *pos+1, lines[*pos].text)
+		}
+		*pos++
+		if val == nil && *pos < len(lines) && lines[*pos].indent > indent {
+			child, err := parseYAMLNode(lines, pos, lines[*pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = child
+			continue
+		}
+		m[key] = val
+	}
+	return m, nil
+}
+
+// splitYAMLKeyVal splits "key: value" / "key:" into its parts. hasKey is
+// false when text isn't a "key: ..." line (a bare scalar sequence item),
+// in which case val holds the parsed scalar.
+func splitYAMLKeyVal(text string) (key string, val interface{}, hasKey bool, err error) {
+	idx := strings.Index(text, ":")
+	for idx >= 0 && idx+1 < len(text) && text[idx+1] != ' ' {
+		next := strings.Index(text[idx+1:], ":")
+		if next < 0 {
+			idx = -1
+			break
+		}
+		idx += 1 + next
+	}
+	if idx < 0 {
+		v, err := parseYAMLScalar(text)
+		return "", v, false, err
+	}
+	key = strings.TrimSpace(text[:idx])
+	valStr := strings.TrimSpace(text[idx+1:])
+	if valStr == "" {
+		return key, nil, true, nil
+	}
+	v, err := parseYAMLScalar(valStr)
+	return key, v, true, err
+}
+
+func parseYAMLScalar(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		if inner == "" {
+			return []interface{}{}, nil
+		}
+		var out []interface{}
+		for _, part := range strings.Split(inner, ",") {
+			v, err := parseYAMLScalar(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	}
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1], nil
+	}
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,40 @@
+package rulevalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+// RuleAtOffset pairs a decoded Rule with the byte offset of its closing
+// `}` in the original input, so diagnostics can point back at the source.
+type RuleAtOffset struct {
+	Rule   pdclient.Rule
+	Offset int64
+}
+
+// ParseWithOffsets decodes a JSON array of rules while recording, for
+// each element, the byte offset in data where it ends. This is what lets
+// Diagnostic.Offset point at real source locations for CI annotations.
+func ParseWithOffsets(data []byte) ([]RuleAtOffset, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return nil, fmt.Errorf("parse rules: expected top-level JSON array")
+	}
+
+	var out []RuleAtOffset
+	for dec.More() {
+		var r pdclient.Rule
+		if err := dec.Decode(&r); err != nil {
+			return nil, fmt.Errorf("parse rules: %w", err)
+		}
+		out = append(out, RuleAtOffset{Rule: r, Offset: dec.InputOffset()})
+	}
+	return out, nil
+}
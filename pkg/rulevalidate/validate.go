@@ -0,0 +1,165 @@
+// Package rulevalidate checks a set of PD placement rules against PD's
+// own semantic invariants, emitting machine-readable diagnostics so
+// broken rule dumps are caught in CI before they're ever POSTed to PD.
+package rulevalidate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/guo-shaoge/change_rules/pkg/pdclient"
+)
+
+// Options tunes checks that depend on cluster configuration rather than
+// being pure rule-shape invariants.
+type Options struct {
+	// MaxReplicas is the cluster's replica limit: the peer count any
+	// overlapping key range within a group may not exceed. Zero disables
+	// the check.
+	MaxReplicas int
+}
+
+// Diagnostic is one violation, addressable by the rule it came from and
+// its byte offset in the original input for CI annotations.
+type Diagnostic struct {
+	RuleIndex int    `json:"rule_index"`
+	GroupID   string `json:"group_id"`
+	ID        string `json:"id"`
+	Offset    int64  `json:"offset"`
+	Message   string `json:"message"`
+}
+
+// Validate runs every invariant against rules and returns all violations
+// found; a nil/empty result means the rule set is valid.
+func Validate(rules []RuleAtOffset, opts Options) []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[[2]string]int) // (GroupID,ID) -> first rule_index seen at
+
+	for i, ro := range rules {
+		r := ro.Rule
+		key := [2]string{r.GroupID, r.ID}
+		if first, ok := seen[key]; ok {
+			diags = append(diags, diag(i, ro, fmt.Sprintf("duplicate (group_id,id): already defined at rules[%d]", first)))
+		} else {
+			seen[key] = i
+		}
+
+		start, errS := hex.DecodeString(r.StartKeyHex)
+		end, errE := hex.DecodeString(r.EndKeyHex)
+		if errS != nil {
+			diags = append(diags, diag(i, ro, fmt.Sprintf("start_key is not valid hex: %v", errS)))
+		}
+		if errE != nil {
+			diags = append(diags, diag(i, ro, fmt.Sprintf("end_key is not valid hex: %v", errE)))
+		}
+		if errS == nil && errE == nil && len(end) > 0 && bytes.Compare(start, end) >= 0 {
+			diags = append(diags, diag(i, ro, "start_key must be < end_key"))
+		}
+
+		for _, con := range r.LabelConstraints {
+			switch con.Op {
+			case pdclient.In, pdclient.NotIn:
+				if len(con.Values) == 0 {
+					diags = append(diags, diag(i, ro, fmt.Sprintf("label_constraints[%s]: values must be non-empty for op %q", con.Key, con.Op)))
+				}
+			case pdclient.Exists, pdclient.NotExists:
+				if len(con.Values) != 0 {
+					diags = append(diags, diag(i, ro, fmt.Sprintf("label_constraints[%s]: values must be empty for op %q", con.Key, con.Op)))
+				}
+			default:
+				diags = append(diags, diag(i, ro, fmt.Sprintf("label_constraints[%s]: unknown op %q", con.Key, con.Op)))
+			}
+		}
+
+		switch r.Role {
+		case pdclient.Voter, pdclient.Leader, pdclient.Follower, pdclient.Learner:
+		default:
+			diags = append(diags, diag(i, ro, fmt.Sprintf("role must be one of voter|leader|follower|learner, got %q", r.Role)))
+		}
+
+		if r.Role == pdclient.Learner && r.IsWitness {
+			diags = append(diags, diag(i, ro, "learner rules cannot set is_witness"))
+		}
+
+		if r.Count <= 0 {
+			diags = append(diags, diag(i, ro, fmt.Sprintf("count must be > 0, got %d", r.Count)))
+		}
+
+		if r.Override && r.Index <= 0 {
+			diags = append(diags, diag(i, ro, "override is only meaningful when index > 0"))
+		}
+	}
+
+	if opts.MaxReplicas > 0 {
+		diags = append(diags, checkReplicaLimits(rules, opts.MaxReplicas)...)
+	}
+
+	return diags
+}
+
+// checkReplicaLimits flags any point in key space where the rules active
+// in a group (summed over every rule whose range contains that point, not
+// just pairs) yield more peers than opts.MaxReplicas. This is a classic
+// interval-stabbing sweep: the maximum overlap count across a group is
+// always attained at one of the rules' own start points, so it's enough
+// to sum every rule active at each start point rather than build a full
+// sweep-line over all boundaries.
+func checkReplicaLimits(rules []RuleAtOffset, maxReplicas int) []Diagnostic {
+	var diags []Diagnostic
+	byGroup := make(map[string][]int)
+	for i, ro := range rules {
+		byGroup[ro.Rule.GroupID] = append(byGroup[ro.Rule.GroupID], i)
+	}
+	for _, idxs := range byGroup {
+		for _, i := range idxs {
+			point, err := hex.DecodeString(rules[i].Rule.StartKeyHex)
+			if err != nil {
+				continue // already reported as an invalid start_key above
+			}
+			var total int
+			var involved []string
+			for _, j := range idxs {
+				rj := rules[j].Rule
+				if !rangeContainsPoint(rj, point) {
+					continue
+				}
+				total += rj.Count
+				involved = append(involved, rj.ID)
+			}
+			if total > maxReplicas {
+				diags = append(diags, diag(i, rules[i], fmt.Sprintf(
+					"rules %v overlap at key %x yielding %d peers, exceeding max_replicas=%d",
+					involved, point, total, maxReplicas)))
+			}
+		}
+	}
+	return diags
+}
+
+// rangeContainsPoint reports whether r's [StartKey, EndKey) contains
+// point. An empty EndKeyHex means "no upper bound".
+func rangeContainsPoint(r pdclient.Rule, point []byte) bool {
+	start, errS := hex.DecodeString(r.StartKeyHex)
+	end, errE := hex.DecodeString(r.EndKeyHex)
+	if errS != nil || errE != nil {
+		return false
+	}
+	if bytes.Compare(point, start) < 0 {
+		return false
+	}
+	if len(end) > 0 && bytes.Compare(point, end) >= 0 {
+		return false
+	}
+	return true
+}
+
+func diag(i int, ro RuleAtOffset, msg string) Diagnostic {
+	return Diagnostic{
+		RuleIndex: i,
+		GroupID:   ro.Rule.GroupID,
+		ID:        ro.Rule.ID,
+		Offset:    ro.Offset,
+		Message:   msg,
+	}
+}
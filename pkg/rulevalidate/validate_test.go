@@ -0,0 +1,92 @@
+package rulevalidate
+
+import (
+	"strings"
+	"testing"
+)
+
+func offsets(jsonDump string) []RuleAtOffset {
+	ros, err := ParseWithOffsets([]byte(jsonDump))
+	if err != nil {
+		panic(err)
+	}
+	return ros
+}
+
+// TestCheckReplicaLimitsJointOverlap covers three rules that pairwise
+// stay within max_replicas but jointly exceed it at a shared point: each
+// pair sums to 4 (under 5), but all three together sum to 6.
+func TestCheckReplicaLimitsJointOverlap(t *testing.T) {
+	dump := `[
+		{"group_id":"tiflash","id":"r1","start_key":"78000000","end_key":"78000010","role":"voter","count":2},
+		{"group_id":"tiflash","id":"r2","start_key":"78000000","end_key":"78000010","role":"voter","count":2},
+		{"group_id":"tiflash","id":"r3","start_key":"78000000","end_key":"78000010","role":"voter","count":2}
+	]`
+	diags := Validate(offsets(dump), Options{MaxReplicas: 5})
+
+	var found bool
+	for _, d := range diags {
+		if strings.Contains(d.Message, "exceeding max_replicas=5") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("want a max_replicas diagnostic, got %+v", diags)
+	}
+}
+
+func TestCheckReplicaLimitsPairwiseOK(t *testing.T) {
+	dump := `[
+		{"group_id":"tiflash","id":"r1","start_key":"78000000","end_key":"78000010","role":"voter","count":2},
+		{"group_id":"tiflash","id":"r2","start_key":"78000000","end_key":"78000010","role":"voter","count":2}
+	]`
+	diags := Validate(offsets(dump), Options{MaxReplicas: 5})
+	for _, d := range diags {
+		if strings.Contains(d.Message, "max_replicas") {
+			t.Fatalf("unexpected max_replicas diagnostic: %+v", d)
+		}
+	}
+}
+
+func TestValidateInvariants(t *testing.T) {
+	dump := `[
+		{"group_id":"tiflash","id":"dup","start_key":"00","end_key":"01","role":"voter","count":1},
+		{"group_id":"tiflash","id":"dup","start_key":"01","end_key":"02","role":"voter","count":1},
+		{"group_id":"tiflash","id":"badrange","start_key":"05","end_key":"01","role":"voter","count":1},
+		{"group_id":"tiflash","id":"zerocount","start_key":"00","end_key":"01","role":"voter","count":0},
+		{"group_id":"tiflash","id":"badrole","start_key":"00","end_key":"01","role":"bogus","count":1},
+		{"group_id":"tiflash","id":"witnesslearner","start_key":"00","end_key":"01","role":"learner","is_witness":true,"count":1},
+		{"group_id":"tiflash","id":"badoverride","start_key":"00","end_key":"01","role":"voter","count":1,"override":true,"index":0},
+		{"group_id":"tiflash","id":"badconstraint","start_key":"00","end_key":"01","role":"voter","count":1,"label_constraints":[{"key":"engine","op":"in","values":[]}]}
+	]`
+	diags := Validate(offsets(dump), Options{})
+
+	wantSubstrings := []string{
+		"duplicate (group_id,id)",
+		"start_key must be < end_key",
+		"count must be > 0",
+		`role must be one of voter|leader|follower|learner, got "bogus"`,
+		"learner rules cannot set is_witness",
+		"override is only meaningful when index > 0",
+		"values must be non-empty for op",
+	}
+	for _, want := range wantSubstrings {
+		var found bool
+		for _, d := range diags {
+			if strings.Contains(d.Message, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("want a diagnostic containing %q, got %+v", want, diags)
+		}
+	}
+}
+
+func TestValidateClean(t *testing.T) {
+	dump := `[{"group_id":"tiflash","id":"r1","start_key":"00","end_key":"01","role":"voter","count":1}]`
+	if diags := Validate(offsets(dump), Options{}); len(diags) != 0 {
+		t.Errorf("want no diagnostics, got %+v", diags)
+	}
+}